@@ -0,0 +1,235 @@
+// Package action implements the chaos actions pumba's subcommands and
+// scheduler dispatch into: stopping, killing and removing containers, and
+// -- via the netem package -- injecting network faults and partitions.
+// The package clause is "actions" (not "action", the import path) so call
+// sites read as actions.Pumba{}, actions.Chaos.
+package actions
+
+import (
+	"context"
+	"fmt"
+	mrand "math/rand"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/gaia-adm/pumba/container"
+	"github.com/gaia-adm/pumba/netem"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const stopTimeout = 10 * time.Second
+
+// Chaos is every fault pumba's subcommands and chaosplan jobs can apply
+// to a set of containers, selected either by exact name or by a re2
+// pattern matched against running container names, with random
+// optionally narrowing that selection down to a single container once
+// it's been resolved (so --random works for re2 patterns too, not just
+// literal names). The NETEM and PARTITION methods take ctx and wg
+// because the fault they install outlives the call: they register its
+// eventual removal on wg so a caller can wg.Wait() before exiting and be
+// sure nothing is left installed, and they bring that removal forward
+// immediately if ctx is cancelled first instead of waiting out the rest
+// of the fault's duration.
+type Chaos interface {
+	StopByName(client container.Client, names []string, random bool) error
+	StopByPattern(client container.Client, pattern string, random bool) error
+	KillByName(client container.Client, names []string, signal string, random bool) error
+	KillByPattern(client container.Client, pattern string, signal string, random bool) error
+	RemoveByName(client container.Client, names []string, force, random bool) error
+	RemoveByPattern(client container.Client, pattern string, force, random bool) error
+
+	NetemDelayByName(ctx context.Context, wg *sync.WaitGroup, client container.Client, names []string, delay, jitter, duration time.Duration, random bool) error
+	NetemDelayByPattern(ctx context.Context, wg *sync.WaitGroup, client container.Client, pattern string, delay, jitter, duration time.Duration, random bool) error
+	NetemLossByName(ctx context.Context, wg *sync.WaitGroup, client container.Client, names []string, percent float64, duration time.Duration, random bool) error
+	NetemLossByPattern(ctx context.Context, wg *sync.WaitGroup, client container.Client, pattern string, percent float64, duration time.Duration, random bool) error
+	NetemCorruptByName(ctx context.Context, wg *sync.WaitGroup, client container.Client, names []string, percent float64, duration time.Duration, random bool) error
+	NetemCorruptByPattern(ctx context.Context, wg *sync.WaitGroup, client container.Client, pattern string, percent float64, duration time.Duration, random bool) error
+	NetemDuplicateByName(ctx context.Context, wg *sync.WaitGroup, client container.Client, names []string, percent float64, duration time.Duration, random bool) error
+	NetemDuplicateByPattern(ctx context.Context, wg *sync.WaitGroup, client container.Client, pattern string, percent float64, duration time.Duration, random bool) error
+
+	PartitionByName(ctx context.Context, wg *sync.WaitGroup, client container.Client, names []string, targets []string, duration time.Duration) error
+	PartitionByPattern(ctx context.Context, wg *sync.WaitGroup, client container.Client, pattern string, targets []string, duration time.Duration) error
+}
+
+// Pumba is the production Chaos implementation.
+type Pumba struct{}
+
+// CheckPrereqs warns about any binary the NETEM/PARTITION actions need
+// that's missing from PATH, so it shows up before the first such action
+// fails. It never returns an error: STOP/KILL/RM don't need those
+// binaries. cleanup is accepted but not used.
+func CheckPrereqs(client container.Client, cleanup bool) error {
+	for _, bin := range []string{"nsenter", "tc", "iptables"} {
+		if _, err := exec.LookPath(bin); err != nil {
+			log.Warnf("%s not found on PATH: NETEM/PARTITION actions will fail", bin)
+		}
+	}
+	return nil
+}
+
+func (Pumba) StopByName(client container.Client, names []string, random bool) error {
+	return forEach(client, names, "", random, "STOP", "", func(c container.Container) error {
+		return client.StopContainer(c, stopTimeout)
+	})
+}
+
+func (Pumba) StopByPattern(client container.Client, pattern string, random bool) error {
+	return forEach(client, nil, pattern, random, "STOP", "", func(c container.Container) error {
+		return client.StopContainer(c, stopTimeout)
+	})
+}
+
+func (Pumba) KillByName(client container.Client, names []string, signal string, random bool) error {
+	return forEach(client, names, "", random, "KILL", signal, func(c container.Container) error {
+		return client.KillContainer(c, signal)
+	})
+}
+
+func (Pumba) KillByPattern(client container.Client, pattern string, signal string, random bool) error {
+	return forEach(client, nil, pattern, random, "KILL", signal, func(c container.Container) error {
+		return client.KillContainer(c, signal)
+	})
+}
+
+func (Pumba) RemoveByName(client container.Client, names []string, force, random bool) error {
+	return forEach(client, names, "", random, "RM", "", func(c container.Container) error {
+		return client.RemoveContainer(c, force, true)
+	})
+}
+
+func (Pumba) RemoveByPattern(client container.Client, pattern string, force, random bool) error {
+	return forEach(client, nil, pattern, random, "RM", "", func(c container.Container) error {
+		return client.RemoveContainer(c, force, true)
+	})
+}
+
+func (Pumba) NetemDelayByName(ctx context.Context, wg *sync.WaitGroup, client container.Client, names []string, delay, jitter, duration time.Duration, random bool) error {
+	return forEach(client, names, "", random, "NETEM", "", func(c container.Container) error {
+		return netem.Delay(ctx, wg, c.Pid, delay, jitter, duration)
+	})
+}
+
+func (Pumba) NetemDelayByPattern(ctx context.Context, wg *sync.WaitGroup, client container.Client, pattern string, delay, jitter, duration time.Duration, random bool) error {
+	return forEach(client, nil, pattern, random, "NETEM", "", func(c container.Container) error {
+		return netem.Delay(ctx, wg, c.Pid, delay, jitter, duration)
+	})
+}
+
+func (Pumba) NetemLossByName(ctx context.Context, wg *sync.WaitGroup, client container.Client, names []string, percent float64, duration time.Duration, random bool) error {
+	return forEach(client, names, "", random, "NETEM", "", func(c container.Container) error {
+		return netem.Loss(ctx, wg, c.Pid, percent, duration)
+	})
+}
+
+func (Pumba) NetemLossByPattern(ctx context.Context, wg *sync.WaitGroup, client container.Client, pattern string, percent float64, duration time.Duration, random bool) error {
+	return forEach(client, nil, pattern, random, "NETEM", "", func(c container.Container) error {
+		return netem.Loss(ctx, wg, c.Pid, percent, duration)
+	})
+}
+
+func (Pumba) NetemCorruptByName(ctx context.Context, wg *sync.WaitGroup, client container.Client, names []string, percent float64, duration time.Duration, random bool) error {
+	return forEach(client, names, "", random, "NETEM", "", func(c container.Container) error {
+		return netem.Corrupt(ctx, wg, c.Pid, percent, duration)
+	})
+}
+
+func (Pumba) NetemCorruptByPattern(ctx context.Context, wg *sync.WaitGroup, client container.Client, pattern string, percent float64, duration time.Duration, random bool) error {
+	return forEach(client, nil, pattern, random, "NETEM", "", func(c container.Container) error {
+		return netem.Corrupt(ctx, wg, c.Pid, percent, duration)
+	})
+}
+
+func (Pumba) NetemDuplicateByName(ctx context.Context, wg *sync.WaitGroup, client container.Client, names []string, percent float64, duration time.Duration, random bool) error {
+	return forEach(client, names, "", random, "NETEM", "", func(c container.Container) error {
+		return netem.Duplicate(ctx, wg, c.Pid, percent, duration)
+	})
+}
+
+func (Pumba) NetemDuplicateByPattern(ctx context.Context, wg *sync.WaitGroup, client container.Client, pattern string, percent float64, duration time.Duration, random bool) error {
+	return forEach(client, nil, pattern, random, "NETEM", "", func(c container.Container) error {
+		return netem.Duplicate(ctx, wg, c.Pid, percent, duration)
+	})
+}
+
+func (Pumba) PartitionByName(ctx context.Context, wg *sync.WaitGroup, client container.Client, names []string, targets []string, duration time.Duration) error {
+	return partition(ctx, wg, client, names, "", targets, duration)
+}
+
+func (Pumba) PartitionByPattern(ctx context.Context, wg *sync.WaitGroup, client container.Client, pattern string, targets []string, duration time.Duration) error {
+	return partition(ctx, wg, client, nil, pattern, targets, duration)
+}
+
+// partition resolves both sides of a PARTITION action to their container
+// IPs and installs the iptables rules between them via netem.Partition.
+// Unlike the other action types, PARTITION has no --random: a partition
+// inherently applies to every container on each side of it.
+func partition(ctx context.Context, wg *sync.WaitGroup, client container.Client, names []string, pattern string, targets []string, duration time.Duration) error {
+	sources, err := client.ListContainers(names, pattern)
+	if err != nil {
+		return err
+	}
+	targetContainers, err := client.ListContainers(targets, "")
+	if err != nil {
+		return err
+	}
+	err = netem.Partition(ctx, wg, ips(sources), ips(targetContainers), duration)
+	for _, c := range sources {
+		logResult("PARTITION", "", c, err)
+	}
+	return err
+}
+
+// forEach resolves names/pattern to the matching containers -- narrowing
+// them down to a single, randomly picked one if random is set and more
+// than one matched -- and applies fn to each, logging the per-container
+// result (with its Docker ID, not just the name a pattern was matched
+// against) before returning the first error encountered.
+func forEach(client container.Client, names []string, pattern string, random bool, action, signal string, fn func(container.Container) error) error {
+	containers, err := client.ListContainers(names, pattern)
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return fmt.Errorf("actions: no running container matched")
+	}
+	if random && len(containers) > 1 {
+		containers = []container.Container{containers[mrand.Intn(len(containers))]}
+	}
+	for _, c := range containers {
+		err := fn(c)
+		logResult(action, signal, c, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logResult records one container's chaos action outcome. It's the only
+// place container_id is known, since names/patterns are resolved to
+// actual Docker containers here; runJob's job-level log line in root.go
+// only has the unresolved match target.
+func logResult(action, signal string, c container.Container, err error) {
+	fields := log.Fields{
+		"action":         action,
+		"container_id":   c.ID,
+		"container_name": c.Name,
+		"signal":         signal,
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		log.WithFields(fields).Error("chaos action failed")
+		return
+	}
+	log.WithFields(fields).Info("chaos action")
+}
+
+func ips(containers []container.Container) []string {
+	out := make([]string, 0, len(containers))
+	for _, c := range containers {
+		out = append(out, c.IP)
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gaia-adm/pumba/container"
+)
+
+// fakeClient is a container.Client double that records which containers
+// each method was called with instead of talking to Docker.
+type fakeClient struct {
+	containers []container.Container
+	listErr    error
+
+	stopped []container.Container
+	killErr error
+}
+
+func (f *fakeClient) ListContainers(names []string, pattern string) ([]container.Container, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	return f.containers, nil
+}
+
+func (f *fakeClient) StopContainer(c container.Container, timeout time.Duration) error {
+	f.stopped = append(f.stopped, c)
+	return nil
+}
+
+func (f *fakeClient) KillContainer(c container.Container, signal string) error {
+	f.stopped = append(f.stopped, c)
+	return f.killErr
+}
+
+func (f *fakeClient) RemoveContainer(c container.Container, force, removeVolumes bool) error {
+	f.stopped = append(f.stopped, c)
+	return nil
+}
+
+func TestStopByNameAppliesToEveryMatch(t *testing.T) {
+	client := &fakeClient{containers: []container.Container{{ID: "a"}, {ID: "b"}}}
+	if err := (Pumba{}).StopByName(client, []string{"a", "b"}, false); err != nil {
+		t.Fatalf("StopByName: %v", err)
+	}
+	if len(client.stopped) != 2 {
+		t.Errorf("stopped %d containers, want 2", len(client.stopped))
+	}
+}
+
+func TestStopByPatternRandomPicksOne(t *testing.T) {
+	client := &fakeClient{containers: []container.Container{{ID: "a"}, {ID: "b"}, {ID: "c"}}}
+	if err := (Pumba{}).StopByPattern(client, "web.*", true); err != nil {
+		t.Fatalf("StopByPattern: %v", err)
+	}
+	if len(client.stopped) != 1 {
+		t.Errorf("stopped %d containers, want 1 (random=true)", len(client.stopped))
+	}
+}
+
+func TestForEachErrorsOnNoMatch(t *testing.T) {
+	client := &fakeClient{}
+	if err := (Pumba{}).StopByName(client, []string{"missing"}, false); err == nil {
+		t.Error("expected an error when no container matched")
+	}
+}
+
+func TestForEachStopsOnFirstError(t *testing.T) {
+	client := &fakeClient{
+		containers: []container.Container{{ID: "a"}, {ID: "b"}},
+		killErr:    errors.New("kill failed"),
+	}
+	if err := (Pumba{}).KillByName(client, []string{"a", "b"}, "SIGKILL", false); err == nil {
+		t.Fatal("expected the kill error to propagate")
+	}
+	if len(client.stopped) != 1 {
+		t.Errorf("called KillContainer %d times, want 1 (stop after first error)", len(client.stopped))
+	}
+}
+
+func TestPartitionByNamePropagatesListError(t *testing.T) {
+	client := &fakeClient{listErr: errors.New("docker unreachable")}
+	var wg sync.WaitGroup
+	err := (Pumba{}).PartitionByName(context.Background(), &wg, client, []string{"a"}, []string{"b"}, time.Millisecond)
+	if err == nil {
+		t.Fatal("expected ListContainers error to propagate")
+	}
+}
@@ -0,0 +1,215 @@
+// Package chaosplan defines a structured, file-based alternative to the
+// pipe-delimited --chaos_cmd flag: a YAML/JSON document describing a list
+// of chaos jobs, each matching containers by name or re2 pattern, running
+// on a schedule, and applying an action such as KILL, NETEM or PARTITION.
+//
+// Legacy --chaos_cmd strings are translated into the same ChaosJob shape
+// via FromChaosCmd, so the scheduler only ever has to deal with one
+// representation regardless of which flag produced it.
+package chaosplan
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const re2prefix = "re2:"
+
+// validTypes are the action types recognised in both chaos plan files
+// (pumba plan) and legacy --chaos_cmd strings.
+var validTypes = []string{"STOP", "KILL", "RM", "NETEM", "PARTITION"}
+
+// Match selects the containers a job applies to, by exact name or by a
+// re2 regular expression. Exactly one of Names or Pattern should be set.
+// Random narrows that selection down to a single, randomly picked
+// container at dispatch time, after Names/Pattern have been resolved to
+// actual running containers -- so it works for pattern matches too,
+// unlike picking a name before resolution would.
+type Match struct {
+	Names   []string `yaml:"names,omitempty"`
+	Pattern string   `yaml:"pattern,omitempty"`
+	Random  bool     `yaml:"random,omitempty"`
+}
+
+// ParseMatch parses a "container(s,)" or "re2:pattern" argument, as
+// accepted by --chaos_cmd and by the cobra subcommands, into a Match.
+func ParseMatch(arg string) Match {
+	if strings.HasPrefix(arg, re2prefix) {
+		return Match{Pattern: strings.TrimPrefix(arg, re2prefix)}
+	}
+	return Match{Names: strings.Split(arg, ",")}
+}
+
+// Schedule controls how often, and how many times, a job's action runs.
+type Schedule struct {
+	Interval   time.Duration `yaml:"interval"`
+	Jitter     time.Duration `yaml:"jitter,omitempty"`
+	Limit      int           `yaml:"limit,omitempty"`
+	StartAfter time.Duration `yaml:"start_after,omitempty"`
+}
+
+// Action describes the fault a job injects into its matched containers.
+// NetemType/Delay/Jitter/Percent/Duration only apply when Type is NETEM;
+// Targets and Duration only apply when Type is PARTITION.
+type Action struct {
+	Type      string        `yaml:"type"`
+	Signal    string        `yaml:"signal,omitempty"`
+	NetemType string        `yaml:"netem_type,omitempty"` // delay|loss|corrupt|duplicate
+	Delay     time.Duration `yaml:"delay,omitempty"`
+	Jitter    time.Duration `yaml:"jitter,omitempty"`
+	Percent   float64       `yaml:"percent,omitempty"`
+	Duration  time.Duration `yaml:"duration,omitempty"`
+	Targets   []string      `yaml:"targets,omitempty"`
+}
+
+// ChaosJob is one scheduled fault: match containers, run on schedule,
+// apply action. It is the shared representation consumed by the
+// scheduler, regardless of whether it originated from a chaos plan
+// file or a legacy --chaos_cmd string.
+type ChaosJob struct {
+	Match    Match    `yaml:"match"`
+	Schedule Schedule `yaml:"schedule"`
+	Action   Action   `yaml:"action"`
+}
+
+// Load reads and parses a chaos plan file. JSON is a valid subset of
+// YAML, so a single unmarshaller handles both formats.
+func Load(path string) ([]ChaosJob, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan struct {
+		Jobs []ChaosJob `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("chaosplan: %s", err)
+	}
+
+	for _, job := range plan.Jobs {
+		if !stringInSlice(job.Action.Type, validTypes) {
+			return nil, fmt.Errorf("chaosplan: unexpected action type %q", job.Action.Type)
+		}
+		if job.Schedule.Interval <= 0 {
+			return nil, fmt.Errorf("chaosplan: schedule.interval must be > 0")
+		}
+		if (len(job.Match.Names) == 0) == (job.Match.Pattern == "") {
+			return nil, fmt.Errorf("chaosplan: match must set exactly one of names or pattern")
+		}
+	}
+	return plan.Jobs, nil
+}
+
+// FromChaosCmd translates a legacy chaos_cmd string --
+// "container(s,)/re2:regex|interval|STOP/KILL(:SIGNAL)/RM/NETEM:.../PARTITION:..."
+// -- into the equivalent ChaosJob.
+func FromChaosCmd(arg string) (ChaosJob, error) {
+	var job ChaosJob
+
+	s := strings.Split(arg, "|")
+	if len(s) != 3 {
+		return job, errors.New("Unexpected format for chaos_cmd: use | separated triple")
+	}
+
+	job.Match = ParseMatch(s[0])
+
+	interval, err := time.ParseDuration(s[1])
+	if err != nil {
+		return job, err
+	}
+	job.Schedule.Interval = interval
+
+	// the action type itself is case-insensitive but netem durations and
+	// percentages are parsed verbatim, so only upper-case the type token
+	cs := strings.Split(s[2], ":")
+	job.Action.Type = strings.ToUpper(cs[0])
+	if !stringInSlice(job.Action.Type, validTypes) {
+		return job, fmt.Errorf("Unexpected command in chaos_cmd: can be %s", strings.Join(validTypes, ", "))
+	}
+
+	switch job.Action.Type {
+	case "KILL":
+		if len(cs) == 2 {
+			job.Action.Signal = strings.ToUpper(cs[1])
+		}
+	case "NETEM":
+		if err := parseNetemAction(&job.Action, cs); err != nil {
+			return job, err
+		}
+	case "PARTITION":
+		if err := parsePartitionAction(&job.Action, cs); err != nil {
+			return job, err
+		}
+	}
+
+	return job, nil
+}
+
+// parseNetemAction fills in the netem fields of a from the colon-separated
+// tail of a NETEM chaos_cmd, e.g. "NETEM:delay:100ms:20ms:30s" or
+// "NETEM:loss:5%:30s". The last token is always the fault duration.
+func parseNetemAction(a *Action, cs []string) error {
+	if len(cs) < 3 {
+		return errors.New("Unexpected format for NETEM chaos_cmd: use NETEM:type:params...:duration")
+	}
+	a.NetemType = strings.ToLower(cs[1])
+
+	duration, err := time.ParseDuration(cs[len(cs)-1])
+	if err != nil {
+		return err
+	}
+	a.Duration = duration
+
+	switch a.NetemType {
+	case "delay":
+		if len(cs) < 4 {
+			return errors.New("Unexpected format for NETEM:delay chaos_cmd: use NETEM:delay:delay:jitter:duration")
+		}
+		if a.Delay, err = time.ParseDuration(cs[2]); err != nil {
+			return err
+		}
+		if len(cs) == 5 {
+			if a.Jitter, err = time.ParseDuration(cs[3]); err != nil {
+				return err
+			}
+		}
+	case "loss", "corrupt", "duplicate":
+		if _, err := fmt.Sscanf(strings.TrimSuffix(cs[2], "%"), "%f", &a.Percent); err != nil {
+			return fmt.Errorf("Unexpected percentage in NETEM chaos_cmd: %s", cs[2])
+		}
+	default:
+		return errors.New("Unexpected NETEM type: can be delay, loss, corrupt or duplicate")
+	}
+	return nil
+}
+
+// parsePartitionAction fills in the partition fields of a from the
+// colon-separated tail of a PARTITION chaos_cmd, e.g.
+// "PARTITION:target1,target2:30s".
+func parsePartitionAction(a *Action, cs []string) error {
+	if len(cs) != 3 {
+		return errors.New("Unexpected format for PARTITION chaos_cmd: use PARTITION:target(s,):duration")
+	}
+	duration, err := time.ParseDuration(cs[2])
+	if err != nil {
+		return err
+	}
+	a.Duration = duration
+	a.Targets = strings.Split(cs[1], ",")
+	return nil
+}
+
+func stringInSlice(a string, list []string) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package chaosplan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFromChaosCmdKill(t *testing.T) {
+	job, err := FromChaosCmd("web1,web2|10s|kill:sigterm")
+	if err != nil {
+		t.Fatalf("FromChaosCmd: %v", err)
+	}
+	if want := []string{"web1", "web2"}; !namesEqual(job.Match.Names, want) {
+		t.Errorf("Names = %v, want %v", job.Match.Names, want)
+	}
+	if job.Schedule.Interval != 10*time.Second {
+		t.Errorf("Interval = %v, want 10s", job.Schedule.Interval)
+	}
+	if job.Action.Type != "KILL" || job.Action.Signal != "SIGTERM" {
+		t.Errorf("Action = %+v, want KILL/SIGTERM", job.Action)
+	}
+}
+
+func TestFromChaosCmdKillDefaultSignal(t *testing.T) {
+	job, err := FromChaosCmd("web1|10s|kill")
+	if err != nil {
+		t.Fatalf("FromChaosCmd: %v", err)
+	}
+	if job.Action.Signal != "" {
+		t.Errorf("Signal = %q, want empty (caller defaults it)", job.Action.Signal)
+	}
+}
+
+func TestFromChaosCmdPattern(t *testing.T) {
+	job, err := FromChaosCmd("re2:web.*|1m|stop")
+	if err != nil {
+		t.Fatalf("FromChaosCmd: %v", err)
+	}
+	if job.Match.Pattern != "web.*" || job.Match.Names != nil {
+		t.Errorf("Match = %+v, want pattern-only web.*", job.Match)
+	}
+}
+
+func TestFromChaosCmdNetemDelay(t *testing.T) {
+	job, err := FromChaosCmd("web1|10s|NETEM:delay:100ms:20ms:30s")
+	if err != nil {
+		t.Fatalf("FromChaosCmd: %v", err)
+	}
+	a := job.Action
+	if a.Type != "NETEM" || a.NetemType != "delay" {
+		t.Fatalf("Action = %+v, want NETEM/delay", a)
+	}
+	if a.Delay != 100*time.Millisecond || a.Jitter != 20*time.Millisecond || a.Duration != 30*time.Second {
+		t.Errorf("Action = %+v, want delay=100ms jitter=20ms duration=30s", a)
+	}
+}
+
+func TestFromChaosCmdNetemDelayNoJitter(t *testing.T) {
+	job, err := FromChaosCmd("web1|10s|NETEM:delay:100ms:30s")
+	if err != nil {
+		t.Fatalf("FromChaosCmd: %v", err)
+	}
+	if job.Action.Jitter != 0 {
+		t.Errorf("Jitter = %v, want 0", job.Action.Jitter)
+	}
+}
+
+func TestFromChaosCmdNetemLoss(t *testing.T) {
+	job, err := FromChaosCmd("web1|10s|NETEM:loss:5%:30s")
+	if err != nil {
+		t.Fatalf("FromChaosCmd: %v", err)
+	}
+	if job.Action.Percent != 5 || job.Action.Duration != 30*time.Second {
+		t.Errorf("Action = %+v, want percent=5 duration=30s", job.Action)
+	}
+}
+
+func TestFromChaosCmdPartition(t *testing.T) {
+	job, err := FromChaosCmd("web1|10s|PARTITION:db1,db2:30s")
+	if err != nil {
+		t.Fatalf("FromChaosCmd: %v", err)
+	}
+	if want := []string{"db1", "db2"}; !namesEqual(job.Action.Targets, want) {
+		t.Errorf("Targets = %v, want %v", job.Action.Targets, want)
+	}
+	if job.Action.Duration != 30*time.Second {
+		t.Errorf("Duration = %v, want 30s", job.Action.Duration)
+	}
+}
+
+func TestFromChaosCmdErrors(t *testing.T) {
+	cases := []string{
+		"web1|10s",                   // wrong arity
+		"web1|not-a-duration|STOP",   // bad interval
+		"web1|10s|EXPLODE",           // unknown action type
+		"web1|10s|NETEM:delay:100ms", // netem missing duration
+	}
+	for _, c := range cases {
+		if _, err := FromChaosCmd(c); err == nil {
+			t.Errorf("FromChaosCmd(%q): expected an error, got none", c)
+		}
+	}
+}
+
+func namesEqual(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+// Package container wraps the Docker API surface pumba needs: listing
+// containers by name or re2 pattern, and stopping/killing/removing them.
+// action.Pumba is the only caller; it never talks to Docker directly.
+package container
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// Container is the subset of a container's state pumba acts on.
+type Container struct {
+	ID   string
+	Name string
+	// IP is the container's primary network IP, used to build iptables
+	// partition rules.
+	IP string
+	// Pid is the container's init process ID in the host PID namespace,
+	// used to enter its network namespace with nsenter for netem faults.
+	Pid int
+}
+
+// Client talks to a single Docker daemon on behalf of action.Pumba.
+type Client interface {
+	// ListContainers returns the running containers matching names (exact,
+	// comma-split names from a chaos_cmd/Match) or pattern (a re2 regular
+	// expression matched against the container name); exactly one of the
+	// two should be set, matching chaosplan.Match.
+	ListContainers(names []string, pattern string) ([]Container, error)
+	StopContainer(c Container, timeout time.Duration) error
+	KillContainer(c Container, signal string) error
+	RemoveContainer(c Container, force, removeVolumes bool) error
+}
+
+// NewClient builds a Client for the given Docker daemon socket/URL. tlsConf
+// is nil unless --tls or --tlsverify was set (--tls-auto alone doesn't
+// enable TLS); pull is stored on the client, but no method of it pulls an
+// image.
+func NewClient(host string, tlsConf *tls.Config, pull bool) Client {
+	c, err := docker.NewClient(host)
+	if err != nil {
+		// NewClient only fails on a malformed endpoint URL; defer the
+		// error to the first real API call rather than complicating
+		// every caller's signature.
+		return &dockerClient{err: err}
+	}
+	if tlsConf != nil {
+		c.TLSConfig = tlsConf
+		c.HTTPClient = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf}}
+	}
+	return &dockerClient{api: c, pull: pull}
+}
+
+type dockerClient struct {
+	api  *docker.Client
+	pull bool
+	err  error
+}
+
+func (d *dockerClient) ListContainers(names []string, pattern string) ([]Container, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("container: invalid pattern %q: %s", pattern, err)
+		}
+	}
+
+	apiContainers, err := d.api.ListContainers(docker.ListContainersOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []Container
+	for _, ac := range apiContainers {
+		name := containerName(ac)
+		if re != nil {
+			if !re.MatchString(name) {
+				continue
+			}
+		} else if !stringInSlice(name, names) {
+			continue
+		}
+
+		details, err := d.api.InspectContainer(ac.ID)
+		if err != nil {
+			return nil, err
+		}
+		matched = append(matched, Container{
+			ID:   details.ID,
+			Name: name,
+			IP:   details.NetworkSettings.IPAddress,
+			Pid:  details.State.Pid,
+		})
+	}
+	return matched, nil
+}
+
+func (d *dockerClient) StopContainer(c Container, timeout time.Duration) error {
+	if d.err != nil {
+		return d.err
+	}
+	return d.api.StopContainer(c.ID, uint(timeout.Seconds()))
+}
+
+func (d *dockerClient) KillContainer(c Container, signal string) error {
+	if d.err != nil {
+		return d.err
+	}
+	num, err := sigNum(signal)
+	if err != nil {
+		return err
+	}
+	return d.api.KillContainer(docker.KillContainerOptions{ID: c.ID, Signal: docker.Signal(num)})
+}
+
+// sigNum maps a "SIGKILL"-style signal name, as accepted by --signal, to
+// its numeric value. An unrecognised name is rejected rather than
+// defaulting to SIGKILL: a config typo should surface as an error, not
+// silently escalate to the most destructive signal pumba has.
+func sigNum(name string) (syscall.Signal, error) {
+	switch strings.ToUpper(strings.TrimPrefix(name, "SIG")) {
+	case "HUP":
+		return syscall.SIGHUP, nil
+	case "INT":
+		return syscall.SIGINT, nil
+	case "QUIT":
+		return syscall.SIGQUIT, nil
+	case "KILL":
+		return syscall.SIGKILL, nil
+	case "USR1":
+		return syscall.SIGUSR1, nil
+	case "USR2":
+		return syscall.SIGUSR2, nil
+	case "TERM":
+		return syscall.SIGTERM, nil
+	case "STOP":
+		return syscall.SIGSTOP, nil
+	case "CONT":
+		return syscall.SIGCONT, nil
+	default:
+		return 0, fmt.Errorf("container: unrecognised signal %q", name)
+	}
+}
+
+func (d *dockerClient) RemoveContainer(c Container, force, removeVolumes bool) error {
+	if d.err != nil {
+		return d.err
+	}
+	return d.api.RemoveContainer(docker.RemoveContainerOptions{ID: c.ID, Force: force, RemoveVolumes: removeVolumes})
+}
+
+// containerName strips the leading "/" Docker prefixes container names
+// with; APIContainers.Names always has at least one entry.
+func containerName(ac docker.APIContainers) string {
+	if len(ac.Names) == 0 {
+		return ac.ID
+	}
+	return strings.TrimPrefix(ac.Names[0], "/")
+}
+
+func stringInSlice(a string, list []string) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package container
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestSigNumRecognisesCommonNames(t *testing.T) {
+	cases := map[string]syscall.Signal{
+		"SIGKILL": syscall.SIGKILL,
+		"KILL":    syscall.SIGKILL,
+		"SIGTERM": syscall.SIGTERM,
+		"term":    syscall.SIGTERM,
+		"SIGSTOP": syscall.SIGSTOP,
+	}
+	for name, want := range cases {
+		got, err := sigNum(name)
+		if err != nil {
+			t.Errorf("sigNum(%q): %v", name, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("sigNum(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestSigNumRejectsUnrecognisedName(t *testing.T) {
+	if _, err := sigNum("SIGBOGUS"); err == nil {
+		t.Error("expected an error for an unrecognised signal name")
+	}
+}
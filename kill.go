@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gaia-adm/pumba/chaosplan"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	killSchedule scheduleFlags
+	killSignal   string
+	killRandom   bool
+)
+
+var killCmd = &cobra.Command{
+	Use:   "kill [flags] container(s,) | re2:pattern",
+	Short: "kill one or more containers, repeatedly, by sending a signal",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runKill,
+}
+
+func init() {
+	killCmd.Annotations = map[string]string{"group": groupOperation}
+	registerScheduleFlags(killCmd.Flags(), &killSchedule, 10*time.Second)
+	killCmd.Flags().StringVar(&killSignal, "signal", defaultKillSignal, "signal to send, e.g. SIGKILL, SIGTERM")
+	killCmd.Flags().BoolVar(&killRandom, "random", false, "kill one randomly picked container instead of every match")
+
+	rootCmd.AddCommand(killCmd)
+}
+
+func runKill(cmd *cobra.Command, args []string) error {
+	match := chaosplan.ParseMatch(args[0])
+	match.Random = killRandom
+
+	job := chaosplan.ChaosJob{
+		Match:    match,
+		Schedule: killSchedule.schedule(),
+		Action:   chaosplan.Action{Type: "KILL", Signal: strings.ToUpper(killSignal)},
+	}
+	return runChaosJobs([]chaosplan.ChaosJob{job})
+}
@@ -0,0 +1,61 @@
+// Package metrics exposes pumba's chaos activity as Prometheus metrics,
+// served over HTTP so operators can scrape and graph it alongside the
+// rest of their stack's SLO dashboards.
+package metrics
+
+import (
+	"net/http"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// ActionsTotal counts every dispatched chaos action, labelled by
+	// action type, target and whether it succeeded or errored.
+	ActionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pumba_actions_total",
+		Help: "Total number of chaos actions dispatched, by action, target and result.",
+	}, []string{"action", "target", "result"})
+
+	// ActionDuration tracks how long a dispatched action took to return.
+	ActionDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "pumba_action_duration_seconds",
+		Help: "Time taken to execute a chaos action.",
+	})
+
+	// TargetsMatched is the number of containers matched by the most
+	// recently dispatched job (only known for name-based matches; pattern
+	// matches are resolved deeper, in action.Chaos).
+	TargetsMatched = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "pumba_targets_matched",
+		Help: "Number of containers matched by the most recently dispatched job.",
+	})
+
+	// SchedulerTicks counts every dispatched tick across all chaos jobs'
+	// tickers, regardless of whether the dispatch succeeded. Ticks in
+	// --dry-run mode are not counted, since dispatch isn't called then.
+	SchedulerTicks = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pumba_scheduler_ticks_total",
+		Help: "Total number of scheduler ticks across all chaos jobs.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(ActionsTotal, ActionDuration, TargetsMatched, SchedulerTicks)
+}
+
+// Serve starts an HTTP server exposing /metrics on addr. It runs in its
+// own goroutine and logs (rather than returns) a failure to bind, since
+// pumba should keep running its chaos schedule even without metrics.
+func Serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.WithField("addr", addr).Error(err)
+		}
+	}()
+}
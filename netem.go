@@ -0,0 +1,113 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gaia-adm/pumba/chaosplan"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	netemSchedule scheduleFlags
+	netemDuration time.Duration
+	netemRandom   bool
+)
+
+// netemCmd is the parent for the network-fault subcommands; it carries the
+// flags common to all of them (--interval, --limit, --jitter,
+// --start-after, --duration, --random) while each child adds its own
+// fault-specific flags.
+var netemCmd = &cobra.Command{
+	Use:   "netem",
+	Short: "network emulation faults: delay, loss, corrupt, duplicate",
+}
+
+func init() {
+	netemCmd.Annotations = map[string]string{"group": groupOperation}
+	registerScheduleFlags(netemCmd.PersistentFlags(), &netemSchedule, 10*time.Second)
+	netemCmd.PersistentFlags().DurationVar(&netemDuration, "duration", 30*time.Second, "how long each fault lasts before it's reverted")
+	netemCmd.PersistentFlags().BoolVar(&netemRandom, "random", false, "apply the fault to one randomly picked container instead of every match")
+
+	netemCmd.AddCommand(netemDelayCmd, netemLossCmd, netemCorruptCmd, netemDuplicateCmd)
+	rootCmd.AddCommand(netemCmd)
+}
+
+var (
+	netemDelay  time.Duration
+	netemJitter time.Duration
+)
+
+var netemDelayCmd = &cobra.Command{
+	Use:   "delay [flags] container(s,) | re2:pattern",
+	Short: "add latency to a container's network interface",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runNetemDelay,
+}
+
+func init() {
+	netemDelayCmd.Flags().DurationVar(&netemDelay, "time", 100*time.Millisecond, "delay to add")
+	netemDelayCmd.Flags().DurationVar(&netemJitter, "delay-jitter", 0, "random jitter added to the delay")
+}
+
+func runNetemDelay(cmd *cobra.Command, args []string) error {
+	return runNetem(args[0], chaosplan.Action{
+		Type: "NETEM", NetemType: "delay",
+		Delay: netemDelay, Jitter: netemJitter, Duration: netemDuration,
+	})
+}
+
+var netemPercent float64
+
+var netemLossCmd = &cobra.Command{
+	Use:   "loss [flags] container(s,) | re2:pattern",
+	Short: "drop a percentage of packets on a container's network interface",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runNetemLoss,
+}
+
+var netemCorruptCmd = &cobra.Command{
+	Use:   "corrupt [flags] container(s,) | re2:pattern",
+	Short: "corrupt a percentage of packets on a container's network interface",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runNetemCorrupt,
+}
+
+var netemDuplicateCmd = &cobra.Command{
+	Use:   "duplicate [flags] container(s,) | re2:pattern",
+	Short: "duplicate a percentage of packets on a container's network interface",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runNetemDuplicate,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{netemLossCmd, netemCorruptCmd, netemDuplicateCmd} {
+		cmd.Flags().Float64Var(&netemPercent, "percent", 5, "percentage of packets affected")
+	}
+}
+
+func runNetemLoss(cmd *cobra.Command, args []string) error {
+	return runNetem(args[0], chaosplan.Action{Type: "NETEM", NetemType: "loss", Percent: netemPercent, Duration: netemDuration})
+}
+
+func runNetemCorrupt(cmd *cobra.Command, args []string) error {
+	return runNetem(args[0], chaosplan.Action{Type: "NETEM", NetemType: "corrupt", Percent: netemPercent, Duration: netemDuration})
+}
+
+func runNetemDuplicate(cmd *cobra.Command, args []string) error {
+	return runNetem(args[0], chaosplan.Action{Type: "NETEM", NetemType: "duplicate", Percent: netemPercent, Duration: netemDuration})
+}
+
+// runNetem builds the shared ChaosJob scaffolding for every netem
+// subcommand, differing only in the Action passed in by the caller.
+func runNetem(arg string, action chaosplan.Action) error {
+	match := chaosplan.ParseMatch(arg)
+	match.Random = netemRandom
+
+	job := chaosplan.ChaosJob{
+		Match:    match,
+		Schedule: netemSchedule.schedule(),
+		Action:   action,
+	}
+	return runChaosJobs([]chaosplan.ChaosJob{job})
+}
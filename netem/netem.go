@@ -0,0 +1,135 @@
+// Package netem programs Linux traffic control (tc/netem) and iptables
+// rules inside a target container's network namespace, so pumba can
+// inject latency, packet loss, corruption, duplication and network
+// partitions, then revert them once the fault duration elapses.
+package netem
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// NetInterface is the network interface pumba targets inside a container's
+// network namespace. Most Docker containers only have "eth0".
+const NetInterface = "eth0"
+
+// execCommand builds the *exec.Cmd run by nsenter/iptablesRule; tests
+// replace it with a double that records the args instead of executing
+// anything.
+var execCommand = exec.Command
+
+// Delay adds latency (with optional jitter) to a container's network
+// interface for the given duration, then removes it. The removal is
+// tracked on wg and brought forward to run immediately if ctx is
+// cancelled first, so a caller can wg.Wait() on shutdown and be sure no
+// fault is left installed.
+func Delay(ctx context.Context, wg *sync.WaitGroup, pid int, delay, jitter, duration time.Duration) error {
+	args := []string{"qdisc", "add", "dev", NetInterface, "root", "netem", "delay", delay.String()}
+	if jitter > 0 {
+		args = append(args, jitter.String(), "distribution", "normal")
+	}
+	return apply(ctx, wg, pid, duration, args)
+}
+
+// Loss drops the given percentage of packets on a container's network
+// interface for the given duration, then removes the fault.
+func Loss(ctx context.Context, wg *sync.WaitGroup, pid int, percent float64, duration time.Duration) error {
+	return apply(ctx, wg, pid, duration, []string{"qdisc", "add", "dev", NetInterface, "root", "netem", "loss", pct(percent)})
+}
+
+// Corrupt introduces the given percentage of packet corruption on a
+// container's network interface for the given duration, then removes it.
+func Corrupt(ctx context.Context, wg *sync.WaitGroup, pid int, percent float64, duration time.Duration) error {
+	return apply(ctx, wg, pid, duration, []string{"qdisc", "add", "dev", NetInterface, "root", "netem", "corrupt", pct(percent)})
+}
+
+// Duplicate duplicates the given percentage of packets on a container's
+// network interface for the given duration, then removes the fault.
+func Duplicate(ctx context.Context, wg *sync.WaitGroup, pid int, percent float64, duration time.Duration) error {
+	return apply(ctx, wg, pid, duration, []string{"qdisc", "add", "dev", NetInterface, "root", "netem", "duplicate", pct(percent)})
+}
+
+// Partition installs iptables DROP rules between two sets of container IPs
+// for the given duration, then reverts them. Like apply, the revert is
+// tracked on wg and brought forward on ctx cancellation.
+func Partition(ctx context.Context, wg *sync.WaitGroup, sourceIPs, targetIPs []string, duration time.Duration) error {
+	for _, src := range sourceIPs {
+		for _, dst := range targetIPs {
+			if err := iptablesRule("-A", src, dst); err != nil {
+				return err
+			}
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		wait(ctx, duration)
+		for _, src := range sourceIPs {
+			for _, dst := range targetIPs {
+				if err := iptablesRule("-D", src, dst); err != nil {
+					log.Error(err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// apply installs a tc qdisc inside the container's network namespace
+// (entered via nsenter) and schedules its removal after duration, or
+// immediately on ctx cancellation if that comes first.
+func apply(ctx context.Context, wg *sync.WaitGroup, pid int, duration time.Duration, qdiscArgs []string) error {
+	if err := nsenter(pid, "tc", qdiscArgs...); err != nil {
+		return err
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		wait(ctx, duration)
+		if err := nsenter(pid, "tc", "qdisc", "del", "dev", NetInterface, "root"); err != nil {
+			log.Error(err)
+		}
+	}()
+	return nil
+}
+
+// wait blocks for duration, or until ctx is cancelled, whichever comes
+// first -- so a pending fault reversal runs right away on shutdown
+// instead of waiting out the rest of its duration.
+func wait(ctx context.Context, duration time.Duration) {
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+	}
+}
+
+// nsenter runs name with args inside the network namespace of pid. Pumba
+// itself must run privileged (or alongside a privileged sidecar) for this
+// to succeed.
+func nsenter(pid int, name string, args ...string) error {
+	nsArgs := append([]string{"-t", fmt.Sprintf("%d", pid), "-n", "--", name}, args...)
+	out, err := execCommand("nsenter", nsArgs...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("netem: %s: %s", err, string(out))
+	}
+	return nil
+}
+
+func iptablesRule(action, src, dst string) error {
+	out, err := execCommand("iptables", action, "FORWARD", "-s", src, "-d", dst, "-j", "DROP").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("netem: %s: %s", err, string(out))
+	}
+	return nil
+}
+
+func pct(percent float64) string {
+	return fmt.Sprintf("%.2f%%", percent)
+}
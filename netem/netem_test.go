@@ -0,0 +1,109 @@
+package netem
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordCommands swaps execCommand for a double that records every
+// command invoked (name plus args) instead of running it, returning a
+// func that restores the original.
+func recordCommands(dst *[][]string) func() {
+	orig := execCommand
+	var mu sync.Mutex
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		mu.Lock()
+		*dst = append(*dst, append([]string{name}, args...))
+		mu.Unlock()
+		return exec.Command("true")
+	}
+	return func() { execCommand = orig }
+}
+
+func TestDelayBuildsTcArgs(t *testing.T) {
+	var got [][]string
+	defer recordCommands(&got)()
+
+	var wg sync.WaitGroup
+	if err := Delay(context.Background(), &wg, 123, 100*time.Millisecond, 20*time.Millisecond, time.Millisecond); err != nil {
+		t.Fatalf("Delay: %v", err)
+	}
+	wg.Wait()
+
+	if len(got) != 2 {
+		t.Fatalf("commands = %v, want 2 (add then del)", got)
+	}
+	wantAdd := []string{"nsenter", "-t", "123", "-n", "--", "tc", "qdisc", "add", "dev", NetInterface, "root", "netem", "delay", "100ms", "20ms", "distribution", "normal"}
+	if !equalArgs(got[0], wantAdd) {
+		t.Errorf("add args = %v, want %v", got[0], wantAdd)
+	}
+	wantDel := []string{"nsenter", "-t", "123", "-n", "--", "tc", "qdisc", "del", "dev", NetInterface, "root"}
+	if !equalArgs(got[1], wantDel) {
+		t.Errorf("del args = %v, want %v", got[1], wantDel)
+	}
+}
+
+func TestLossBuildsPercentArg(t *testing.T) {
+	var got [][]string
+	defer recordCommands(&got)()
+
+	var wg sync.WaitGroup
+	if err := Loss(context.Background(), &wg, 1, 5.5, time.Millisecond); err != nil {
+		t.Fatalf("Loss: %v", err)
+	}
+	wg.Wait()
+
+	want := []string{"nsenter", "-t", "1", "-n", "--", "tc", "qdisc", "add", "dev", NetInterface, "root", "netem", "loss", "5.50%"}
+	if !equalArgs(got[0], want) {
+		t.Errorf("args = %v, want %v", got[0], want)
+	}
+}
+
+func TestPartitionInstallsAndRevertsIptablesRules(t *testing.T) {
+	var got [][]string
+	defer recordCommands(&got)()
+
+	var wg sync.WaitGroup
+	if err := Partition(context.Background(), &wg, []string{"10.0.0.1"}, []string{"10.0.0.2"}, time.Millisecond); err != nil {
+		t.Fatalf("Partition: %v", err)
+	}
+	wg.Wait()
+
+	if len(got) != 2 {
+		t.Fatalf("commands = %v, want 2 (add rule then delete rule)", got)
+	}
+	wantAdd := []string{"iptables", "-A", "FORWARD", "-s", "10.0.0.1", "-d", "10.0.0.2", "-j", "DROP"}
+	if !equalArgs(got[0], wantAdd) {
+		t.Errorf("add rule = %v, want %v", got[0], wantAdd)
+	}
+	wantDel := []string{"iptables", "-D", "FORWARD", "-s", "10.0.0.1", "-d", "10.0.0.2", "-j", "DROP"}
+	if !equalArgs(got[1], wantDel) {
+		t.Errorf("delete rule = %v, want %v", got[1], wantDel)
+	}
+}
+
+func TestWaitReturnsEarlyOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	wait(ctx, time.Hour)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("wait took %v, want to return immediately on a cancelled ctx", elapsed)
+	}
+}
+
+func equalArgs(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/gaia-adm/pumba/chaosplan"
+
+	"github.com/spf13/cobra"
+)
+
+var planLegacyCmd []string
+
+// planCmd runs a chaos plan: a YAML/JSON file (file.yaml), one or more
+// legacy --legacy-cmd strings in the old --chaos_cmd format, or both at
+// once -- both are translated into the same []chaosplan.ChaosJob before
+// being handed to the scheduler.
+var planCmd = &cobra.Command{
+	Use:   "plan [flags] [file.yaml]",
+	Short: "run a chaos plan: a file of scheduled jobs and/or legacy --chaos_cmd strings",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runPlan,
+}
+
+func init() {
+	planCmd.Annotations = map[string]string{"group": groupOperation}
+	planCmd.Flags().StringArrayVar(&planLegacyCmd, "legacy-cmd", nil, "chaos_cmd string, in the pre-cobra `container(s,)/re2:regex|interval|STOP/KILL(:SIGNAL)/RM/NETEM:.../PARTITION:...` format; repeatable")
+	rootCmd.AddCommand(planCmd)
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && len(planLegacyCmd) == 0 {
+		return errors.New("pumba plan: need a plan file and/or at least one --legacy-cmd")
+	}
+
+	var jobs []chaosplan.ChaosJob
+	if len(args) == 1 {
+		fileJobs, err := chaosplan.Load(args[0])
+		if err != nil {
+			return err
+		}
+		jobs = append(jobs, fileJobs...)
+	}
+
+	for _, arg := range planLegacyCmd {
+		job, err := chaosplan.FromChaosCmd(arg)
+		if err != nil {
+			return err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return runChaosJobs(jobs)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gaia-adm/pumba/chaosplan"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rmSchedule scheduleFlags
+	rmRandom   bool
+)
+
+var rmCmd = &cobra.Command{
+	Use:   "rm [flags] container(s,) | re2:pattern",
+	Short: "remove one or more containers, repeatedly",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRm,
+}
+
+func init() {
+	rmCmd.Annotations = map[string]string{"group": groupOperation}
+	registerScheduleFlags(rmCmd.Flags(), &rmSchedule, 10*time.Second)
+	rmCmd.Flags().BoolVar(&rmRandom, "random", false, "remove one randomly picked container instead of every match")
+
+	rootCmd.AddCommand(rmCmd)
+}
+
+func runRm(cmd *cobra.Command, args []string) error {
+	match := chaosplan.ParseMatch(args[0])
+	match.Random = rmRandom
+
+	job := chaosplan.ChaosJob{
+		Match:    match,
+		Schedule: rmSchedule.schedule(),
+		Action:   chaosplan.Action{Type: "RM"},
+	}
+	return runChaosJobs([]chaosplan.ChaosJob{job})
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gaia-adm/pumba/action"
+	"github.com/gaia-adm/pumba/chaosplan"
+	"github.com/gaia-adm/pumba/container"
+	"github.com/gaia-adm/pumba/metrics"
+	"github.com/gaia-adm/pumba/scheduler"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// command groups, used by the usage template to separate day-to-day chaos
+// commands from anything that manages pumba itself.
+const (
+	groupManagement = "management"
+	groupOperation  = "operation"
+)
+
+const defaultKillSignal = "SIGKILL"
+
+var (
+	wg      sync.WaitGroup
+	client  container.Client
+	cleanup bool
+)
+
+// persistent (global) flags, shared by every subcommand
+var (
+	host        string
+	tlsEnabled  bool
+	tlsVerify   bool
+	tlsCACert   string
+	tlsCert     string
+	tlsKey      string
+	tlsAuto     bool
+	noPull      bool
+	debug       bool
+	metricsAddr string
+	logJSON     bool
+	dryRun      bool
+)
+
+// rootCmd is the pumba base command. Operation subcommands (kill, stop,
+// rm, netem, plan) register themselves on it from their own files' init().
+var rootCmd = &cobra.Command{
+	Use:           "pumba",
+	Short:         "Pumba is a resiliency tool that helps applications tolerate random Docker container failures",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if debug {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		if logJSON {
+			log.SetFormatter(&log.JSONFormatter{})
+		}
+
+		if metricsAddr != "" {
+			metrics.Serve(metricsAddr)
+		}
+
+		tlsConf, err := tlsConfig()
+		if err != nil {
+			return err
+		}
+		client = container.NewClient(host, tlsConf, !noPull)
+
+		return actions.CheckPrereqs(client, cleanup)
+	},
+}
+
+func init() {
+	log.SetLevel(log.InfoLevel)
+
+	rootCertPath := "/etc/ssl/docker"
+	if os.Getenv("DOCKER_CERT_PATH") != "" {
+		rootCertPath = os.Getenv("DOCKER_CERT_PATH")
+	}
+
+	flags := rootCmd.PersistentFlags()
+	flags.StringVarP(&host, "host", "H", "unix:///var/run/docker.sock", "daemon socket to connect to")
+	flags.BoolVar(&tlsEnabled, "tls", false, "use TLS; implied by --tlsverify")
+	flags.BoolVar(&tlsVerify, "tlsverify", false, "use TLS and verify the remote")
+	flags.StringVar(&tlsCACert, "tlscacert", fmt.Sprintf("%s/ca.pem", rootCertPath), "trust certs signed only by this CA")
+	flags.StringVar(&tlsCert, "tlscert", fmt.Sprintf("%s/cert.pem", rootCertPath), "client certificate for TLS authentication")
+	flags.StringVar(&tlsKey, "tlskey", fmt.Sprintf("%s/key.pem", rootCertPath), "client key for TLS authentication")
+	flags.BoolVar(&tlsAuto, "tls-auto", false, "generate an ephemeral, self-signed client certificate instead of loading --tlscert/--tlskey")
+	flags.BoolVar(&noPull, "no-pull", false, "has no effect: pumba runs no helper image and pulls no image")
+	flags.BoolVar(&cleanup, "cleanup", false, "has no effect: no container image is removed")
+	flags.BoolVar(&debug, "debug", false, "enable debug mode with verbose logging")
+	flags.StringVar(&metricsAddr, "metrics-addr", "", "expose Prometheus metrics, e.g. :8080")
+	flags.BoolVar(&logJSON, "log-json", false, "log chaos actions as structured JSON instead of plain text")
+	flags.BoolVar(&dryRun, "dry-run", false, "log what chaos actions would run, without invoking them")
+
+	SetupRootCommand(rootCmd)
+}
+
+// SetupRootCommand installs the shared usage template and flag-error
+// behavior onto cmd. Call it once, on the root command.
+func SetupRootCommand(cmd *cobra.Command) {
+	cmd.SetUsageTemplate(usageTemplate)
+	cmd.SetFlagErrorFunc(flagErrorFunc)
+}
+
+// flagErrorFunc returns a short, actionable error instead of the full
+// usage block cobra prints by default on every flag typo.
+func flagErrorFunc(cmd *cobra.Command, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s\nSee '%s --help'", err, cmd.CommandPath())
+}
+
+// usageTemplate groups subcommands into "Management Commands" and
+// "Operation Commands", matching Docker CLI ergonomics.
+const usageTemplate = `Usage:{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}
+{{if .HasAvailableSubCommands}}
+Management Commands:{{range .Commands}}{{if (and .IsAvailableCommand (eq (index .Annotations "group") "management"))}}
+  {{rpad .Name .NamePadding}} {{.Short}}{{end}}{{end}}
+
+Operation Commands:{{range .Commands}}{{if (and .IsAvailableCommand (eq (index .Annotations "group") "operation"))}}
+  {{rpad .Name .NamePadding}} {{.Short}}{{end}}{{end}}{{end}}
+
+Flags:
+{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}
+
+Global Flags:
+{{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}
+{{if .HasAvailableSubCommands}}
+Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}
+`
+
+// handleSignals cancels the scheduler on SIGINT/SIGTERM, waits for any
+// in-flight actions to drain, then exits.
+func handleSignals(cancel context.CancelFunc) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	signal.Notify(c, syscall.SIGTERM)
+
+	go func() {
+		<-c
+		cancel()
+		wg.Wait()
+		os.Exit(1)
+	}()
+}
+
+// tlsConfig translates the global TLS flags into a tls.Config struct
+func tlsConfig() (*tls.Config, error) {
+	var tlsConfig *tls.Config
+	var err error
+
+	if tlsEnabled || tlsVerify {
+		tlsConfig = &tls.Config{
+			InsecureSkipVerify: !tlsVerify,
+		}
+
+		// Load CA cert
+		if tlsCACert != "" {
+			var caCert []byte
+
+			if strings.HasPrefix(tlsCACert, "/") {
+				caCert, err = ioutil.ReadFile(tlsCACert)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				caCert = []byte(tlsCACert)
+			}
+
+			caCertPool := x509.NewCertPool()
+			caCertPool.AppendCertsFromPEM(caCert)
+
+			tlsConfig.RootCAs = caCertPool
+		}
+
+		// Load (or generate) the client certificate
+		if tlsAuto {
+			cert, err := generateAutoCert(host)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		} else if tlsCert != "" && tlsKey != "" {
+			var cert tls.Certificate
+
+			if strings.HasPrefix(tlsCert, "/") && strings.HasPrefix(tlsKey, "/") {
+				cert, err = tls.LoadX509KeyPair(tlsCert, tlsKey)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				cert, err = tls.X509KeyPair([]byte(tlsCert), []byte(tlsKey))
+				if err != nil {
+					return nil, err
+				}
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// generateAutoCert creates an ephemeral ECDSA P-256 keypair and a
+// self-signed client certificate, kept in memory for this pumba process
+// only and valid for 24 hours (--tls-auto), for local dev and CI where mounting
+// /etc/ssl/docker is inconvenient. dockerHost is parsed for a DNS name or
+// IP to embed as the certificate's subject alternative name.
+func generateAutoCert(dockerHost string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := crand.Int(crand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "pumba-tls-auto"},
+		NotBefore:    now,
+		NotAfter:     now.Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	if u, err := url.Parse(dockerHost); err == nil && u.Hostname() != "" {
+		if ip := net.ParseIP(u.Hostname()); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, u.Hostname())
+		}
+	}
+
+	der, err := x509.CreateCertificate(crand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}, nil
+}
+
+// scheduleFlags holds the --interval/--limit/--jitter/--start-after flags
+// shared by every operation subcommand; registerScheduleFlags wires them
+// into a command's flag set, and schedule() turns the parsed values into
+// the chaosplan.Schedule the scheduler package consumes.
+type scheduleFlags struct {
+	interval   time.Duration
+	limit      int
+	jitter     time.Duration
+	startAfter time.Duration
+}
+
+func registerScheduleFlags(fs *pflag.FlagSet, sf *scheduleFlags, defaultInterval time.Duration) {
+	fs.DurationVar(&sf.interval, "interval", defaultInterval, "interval between actions")
+	fs.IntVar(&sf.limit, "limit", 0, "stop after this many executions (0 = run forever)")
+	fs.DurationVar(&sf.jitter, "jitter", 0, "random jitter added to the interval on each tick")
+	fs.DurationVar(&sf.startAfter, "start-after", 0, "delay before the first execution")
+}
+
+func (sf scheduleFlags) schedule() chaosplan.Schedule {
+	return chaosplan.Schedule{Interval: sf.interval, Jitter: sf.jitter, Limit: sf.limit, StartAfter: sf.startAfter}
+}
+
+// runChaosJobs runs jobs to completion via the scheduler package: one
+// goroutine per job, each on its own ticker (honoring Schedule.Interval,
+// Jitter, Limit and StartAfter). It blocks until every job has stopped,
+// either because it hit its own Schedule.Limit (a one-off "kill --limit
+// 1" returns as soon as that single execution is done) or because
+// SIGINT/SIGTERM cancelled them all. Before returning it also waits for
+// every NETEM/PARTITION fault those jobs installed to be removed.
+func runChaosJobs(jobs []chaosplan.ChaosJob) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	handleSignals(cancel)
+
+	sched := scheduler.New(dryRun)
+	sched.Run(ctx, jobs, func(job chaosplan.ChaosJob) error {
+		metrics.SchedulerTicks.Inc()
+		wg.Add(1)
+		defer wg.Done()
+		runJob(ctx, actions.Pumba{}, job)
+		return nil
+	})
+
+	sched.Wait()
+	wg.Wait()
+	return nil
+}
+
+// runJob dispatches job and records its outcome as Prometheus metrics
+// plus a structured log line, so operators can correlate chaos activity
+// with SLO dashboards. The per-container result (with container ID) is
+// logged one level down, inside action.Chaos, once it resolves pattern
+// matches to actual containers; this line is the job-level summary. ctx
+// is only consulted for NETEM/PARTITION actions, whose installed fault
+// outlives this call: it lets a pending removal run immediately on
+// shutdown instead of waiting out the rest of the fault's duration.
+func runJob(ctx context.Context, chaos actions.Chaos, job chaosplan.ChaosJob) {
+	target := jobTarget(job)
+	if job.Match.Pattern == "" {
+		metrics.TargetsMatched.Set(float64(len(job.Match.Names)))
+	}
+
+	start := time.Now()
+	err := dispatch(ctx, chaos, job)
+	metrics.ActionDuration.Observe(time.Since(start).Seconds())
+
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	metrics.ActionsTotal.WithLabelValues(job.Action.Type, target, result).Inc()
+
+	fields := log.Fields{
+		"action":         job.Action.Type,
+		"container_name": target,
+		"signal":         job.Action.Signal,
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		log.WithFields(fields).Error("chaos action failed")
+		return
+	}
+	log.WithFields(fields).Info("chaos action")
+}
+
+// jobTarget renders a job's Match as a single label/log value.
+func jobTarget(job chaosplan.ChaosJob) string {
+	if job.Match.Pattern != "" {
+		return "re2:" + job.Match.Pattern
+	}
+	return strings.Join(job.Match.Names, ",")
+}
+
+// dispatch routes a ChaosJob to the matching action.Chaos method.
+func dispatch(ctx context.Context, chaos actions.Chaos, job chaosplan.ChaosJob) error {
+	byPattern := job.Match.Pattern != ""
+	random := job.Match.Random
+	switch job.Action.Type {
+	case "STOP":
+		if byPattern {
+			return chaos.StopByPattern(client, job.Match.Pattern, random)
+		}
+		return chaos.StopByName(client, job.Match.Names, random)
+	case "KILL":
+		signal := job.Action.Signal
+		if signal == "" {
+			signal = defaultKillSignal
+		}
+		if byPattern {
+			return chaos.KillByPattern(client, job.Match.Pattern, signal, random)
+		}
+		return chaos.KillByName(client, job.Match.Names, signal, random)
+	case "RM":
+		if byPattern {
+			return chaos.RemoveByPattern(client, job.Match.Pattern, true, random)
+		}
+		return chaos.RemoveByName(client, job.Match.Names, true, random)
+	case "NETEM":
+		return dispatchNetem(ctx, chaos, job)
+	case "PARTITION":
+		if byPattern {
+			return chaos.PartitionByPattern(ctx, &wg, client, job.Match.Pattern, job.Action.Targets, job.Action.Duration)
+		}
+		return chaos.PartitionByName(ctx, &wg, client, job.Match.Names, job.Action.Targets, job.Action.Duration)
+	}
+	return fmt.Errorf("Unexpected action type in chaos job: %s", job.Action.Type)
+}
+
+// dispatchNetem routes a NETEM ChaosJob to the matching action.Chaos
+// method, which resolves the target container(s) to a network namespace
+// and programs the fault via the netem package.
+func dispatchNetem(ctx context.Context, chaos actions.Chaos, job chaosplan.ChaosJob) error {
+	byPattern := job.Match.Pattern != ""
+	random := job.Match.Random
+	a := job.Action
+	switch a.NetemType {
+	case "delay":
+		if byPattern {
+			return chaos.NetemDelayByPattern(ctx, &wg, client, job.Match.Pattern, a.Delay, a.Jitter, a.Duration, random)
+		}
+		return chaos.NetemDelayByName(ctx, &wg, client, job.Match.Names, a.Delay, a.Jitter, a.Duration, random)
+	case "loss":
+		if byPattern {
+			return chaos.NetemLossByPattern(ctx, &wg, client, job.Match.Pattern, a.Percent, a.Duration, random)
+		}
+		return chaos.NetemLossByName(ctx, &wg, client, job.Match.Names, a.Percent, a.Duration, random)
+	case "corrupt":
+		if byPattern {
+			return chaos.NetemCorruptByPattern(ctx, &wg, client, job.Match.Pattern, a.Percent, a.Duration, random)
+		}
+		return chaos.NetemCorruptByName(ctx, &wg, client, job.Match.Names, a.Percent, a.Duration, random)
+	case "duplicate":
+		if byPattern {
+			return chaos.NetemDuplicateByPattern(ctx, &wg, client, job.Match.Pattern, a.Percent, a.Duration, random)
+		}
+		return chaos.NetemDuplicateByName(ctx, &wg, client, job.Match.Names, a.Percent, a.Duration, random)
+	}
+	return fmt.Errorf("Unexpected NETEM type: %s", a.NetemType)
+}
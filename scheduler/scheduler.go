@@ -0,0 +1,115 @@
+// Package scheduler runs a set of chaos jobs concurrently, one goroutine
+// per job, each owning its own ticker so a slow or long-running job can
+// never block the others. It replaces the original single shared-channel
+// loop in main.go, which only ever ran the first chaos_cmd: the range
+// over dc nested inside the range over args meant later args were never
+// reached, and the shared close(dc) on limit could tear down a ticker
+// that didn't belong to it.
+package scheduler
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/gaia-adm/pumba/chaosplan"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// DispatchFunc applies a single chaos job tick; the scheduler calls it
+// once per tick, per job, possibly concurrently across jobs.
+type DispatchFunc func(job chaosplan.ChaosJob) error
+
+// Scheduler runs chaos jobs on their own schedules until their context is
+// cancelled or, for jobs with a Schedule.Limit, until they've run that
+// many times.
+type Scheduler struct {
+	// DryRun logs what each tick would have done instead of calling
+	// dispatch, for rehearsing a chaos plan without touching containers.
+	DryRun bool
+
+	wg sync.WaitGroup
+}
+
+// New creates a Scheduler.
+func New(dryRun bool) *Scheduler {
+	return &Scheduler{DryRun: dryRun}
+}
+
+// Run starts one goroutine per job and returns immediately. Each job's
+// goroutine runs until ctx is cancelled or, for jobs with a
+// Schedule.Limit, until it's executed that many times; call Wait to
+// block until every job has stopped for either reason.
+func (s *Scheduler) Run(ctx context.Context, jobs []chaosplan.ChaosJob, dispatch DispatchFunc) {
+	for _, job := range jobs {
+		s.wg.Add(1)
+		go func(job chaosplan.ChaosJob) {
+			defer s.wg.Done()
+			s.runJob(ctx, job, dispatch)
+		}(job)
+	}
+}
+
+// Wait blocks until every job started by Run has stopped, whether
+// because it hit its Schedule.Limit or because ctx was cancelled.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// runJob waits out the job's start_after delay, then ticks at
+// interval+jitter until ctx is cancelled or the job's limit is reached.
+func (s *Scheduler) runJob(ctx context.Context, job chaosplan.ChaosJob, dispatch DispatchFunc) {
+	if job.Schedule.StartAfter > 0 {
+		if !sleep(ctx, job.Schedule.StartAfter) {
+			return
+		}
+	}
+
+	var executions int
+	for {
+		if !sleep(ctx, jitter(job.Schedule.Interval, job.Schedule.Jitter)) {
+			return
+		}
+
+		if s.DryRun {
+			log.WithFields(log.Fields{
+				"action": job.Action.Type,
+				"match":  job.Match,
+			}).Info("dry-run: would dispatch chaos action")
+		} else if err := dispatch(job); err != nil {
+			log.Error(err)
+		}
+
+		executions++
+		if job.Schedule.Limit > 0 && executions >= job.Schedule.Limit {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+// jitter adds a uniform random offset in [0, jitter) to interval.
+func jitter(interval, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Int63n(int64(jitter)))
+}
+
+// sleep waits for d or until ctx is cancelled, reporting which happened
+// first so callers can bail out cleanly on cancellation.
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gaia-adm/pumba/chaosplan"
+)
+
+func TestJitterZeroReturnsInterval(t *testing.T) {
+	if got := jitter(5*time.Second, 0); got != 5*time.Second {
+		t.Errorf("jitter(5s, 0) = %v, want 5s", got)
+	}
+}
+
+func TestJitterBounds(t *testing.T) {
+	interval, amount := 5*time.Second, 2*time.Second
+	for i := 0; i < 100; i++ {
+		got := jitter(interval, amount)
+		if got < interval || got >= interval+amount {
+			t.Fatalf("jitter(%v, %v) = %v, want in [%v, %v)", interval, amount, got, interval, interval+amount)
+		}
+	}
+}
+
+func TestRunStopsAtLimit(t *testing.T) {
+	job := chaosplan.ChaosJob{
+		Schedule: chaosplan.Schedule{Interval: time.Millisecond, Limit: 3},
+	}
+
+	var executions int32
+	s := New(false)
+	s.Run(context.Background(), []chaosplan.ChaosJob{job}, func(chaosplan.ChaosJob) error {
+		atomic.AddInt32(&executions, 1)
+		return nil
+	})
+
+	waitOrTimeout(t, s.Wait)
+
+	if got := atomic.LoadInt32(&executions); got != 3 {
+		t.Errorf("executions = %d, want 3", got)
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	job := chaosplan.ChaosJob{
+		Schedule: chaosplan.Schedule{Interval: time.Millisecond},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := New(false)
+	s.Run(ctx, []chaosplan.ChaosJob{job}, func(chaosplan.ChaosJob) error { return nil })
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	waitOrTimeout(t, s.Wait)
+}
+
+func TestRunDryRunNeverDispatches(t *testing.T) {
+	job := chaosplan.ChaosJob{
+		Schedule: chaosplan.Schedule{Interval: time.Millisecond, Limit: 3},
+	}
+
+	var dispatched int32
+	s := New(true)
+	s.Run(context.Background(), []chaosplan.ChaosJob{job}, func(chaosplan.ChaosJob) error {
+		atomic.AddInt32(&dispatched, 1)
+		return nil
+	})
+
+	waitOrTimeout(t, s.Wait)
+
+	if got := atomic.LoadInt32(&dispatched); got != 0 {
+		t.Errorf("dispatched = %d, want 0 (DryRun must not call dispatch)", got)
+	}
+}
+
+// waitOrTimeout fails the test rather than hanging forever if wait never
+// returns, since Scheduler.Wait has no built-in timeout of its own.
+func waitOrTimeout(t *testing.T, wait func()) {
+	t.Helper()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		wait()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for scheduler to stop")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gaia-adm/pumba/chaosplan"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	stopSchedule scheduleFlags
+	stopRandom   bool
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop [flags] container(s,) | re2:pattern",
+	Short: "stop one or more containers, repeatedly",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runStop,
+}
+
+func init() {
+	stopCmd.Annotations = map[string]string{"group": groupOperation}
+	registerScheduleFlags(stopCmd.Flags(), &stopSchedule, 10*time.Second)
+	stopCmd.Flags().BoolVar(&stopRandom, "random", false, "stop one randomly picked container instead of every match")
+
+	rootCmd.AddCommand(stopCmd)
+}
+
+func runStop(cmd *cobra.Command, args []string) error {
+	match := chaosplan.ParseMatch(args[0])
+	match.Random = stopRandom
+
+	job := chaosplan.ChaosJob{
+		Match:    match,
+		Schedule: stopSchedule.schedule(),
+		Action:   chaosplan.Action{Type: "STOP"},
+	}
+	return runChaosJobs([]chaosplan.ChaosJob{job})
+}